@@ -0,0 +1,66 @@
+#!/usr/bin/env bash
+# Coverage helpers used by the Makefile.
+#
+#   coverage.sh check    write the profile and report, then fail if total
+#                        coverage is below MIN_COVERAGE (percent, default 70)
+#   coverage.sh profile  write the profile and report only, no threshold
+#   coverage.sh html     write the profile and render coverage.html
+#
+# The profile goes to coverage.out (ignored); the function-level report goes
+# to coverage.txt, which is committed to track progress over time.
+set -euo pipefail
+
+MODE="${1:-check}"
+MIN_COVERAGE="${MIN_COVERAGE:-70}"
+PROFILE="${COVERAGE_PROFILE:-coverage.out}"
+REPORT="${COVERAGE_REPORT:-coverage.txt}"
+
+cd "$(dirname "$0")/.."
+
+case "$MODE" in
+check | profile | html) ;;
+*)
+	echo "usage: $0 [check|profile|html]" >&2
+	exit 2
+	;;
+esac
+
+if ! packages="$(go list ./...)"; then
+	echo "coverage: go list failed" >&2
+	exit 1
+fi
+if [ -z "$packages" ]; then
+	echo "coverage: no Go packages found, nothing to do"
+	exit 0
+fi
+
+# Keep going on test failures so the report is still written; the status is
+# returned at the end.
+test_status=0
+go test -coverprofile="$PROFILE" ./... || test_status=$?
+if [ ! -s "$PROFILE" ]; then
+	echo "coverage: go test did not write $PROFILE" >&2
+	exit 1
+fi
+
+go tool cover -func="$PROFILE" >"$REPORT"
+total="$(awk '/^total:/ { sub(/%/, "", $3); print $3 }' "$REPORT")"
+if [ -z "$total" ]; then
+	echo "coverage: no total line in $REPORT" >&2
+	exit 1
+fi
+echo "coverage: total ${total}% (minimum ${MIN_COVERAGE}%)"
+
+if [ "$MODE" = html ]; then
+	go tool cover -html="$PROFILE" -o coverage.html
+	echo "coverage: open coverage.html in a browser to view the report"
+fi
+
+if [ "$test_status" -ne 0 ]; then
+	exit "$test_status"
+fi
+
+if [ "$MODE" = check ] && awk -v t="$total" -v m="$MIN_COVERAGE" 'BEGIN { exit !(t < m) }'; then
+	echo "coverage: below minimum" >&2
+	exit 1
+fi